@@ -0,0 +1,38 @@
+package libcontainer
+
+import (
+	"strings"
+
+	"github.com/docker/libcontainer/cgroups"
+	"github.com/docker/libcontainer/cgroups/fs"
+	"github.com/docker/libcontainer/cgroups/systemd"
+	"github.com/docker/libcontainer/configs"
+)
+
+// newCgroupManager picks the cgroups.Manager backend for cg based on its
+// Parent string: a parent shaped like a systemd unit path
+// ("machine.slice/foo.scope", or a bare slice name like "user.slice") goes
+// through a systemd transient scope, provided the systemd private D-Bus
+// socket is actually reachable; everything else -- a plain cgroupfs path,
+// or a systemd-shaped parent on a host with no systemd -- falls back to
+// driving cgroupfs directly.
+func newCgroupManager(cg *configs.Cgroup) cgroups.Manager {
+	if usesSystemdUnit(cg.Parent) && systemd.UseSystemd() {
+		return &systemd.Manager{Cgroups: cg}
+	}
+	return &fs.Manager{Cgroups: cg}
+}
+
+// usesSystemdUnit reports whether parent names a systemd slice/scope
+// rather than a plain cgroupfs path, e.g. "machine.slice/foo.scope" or a
+// bare "user.slice".
+func usesSystemdUnit(parent string) bool {
+	if parent == "" {
+		return false
+	}
+	last := parent
+	if i := strings.LastIndex(parent, "/"); i >= 0 {
+		last = parent[i+1:]
+	}
+	return strings.HasSuffix(last, ".slice") || strings.HasSuffix(last, ".scope")
+}
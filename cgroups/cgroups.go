@@ -0,0 +1,41 @@
+// Package cgroups defines the interface every cgroup backend libcontainer
+// can drive a container's resource limits through must implement, along
+// with the shared types (Stats, errors, mountpoint helpers) those backends
+// build on. cgroups/fs implements it directly against cgroupfs, delegating
+// to cgroups/fs2 on a unified (v2) hierarchy; cgroups/systemd implements it
+// by starting a systemd transient scope.
+package cgroups
+
+import (
+	"github.com/docker/libcontainer/configs"
+)
+
+// Manager is implemented by every cgroup backend.
+type Manager interface {
+	// Apply creates a cgroup, as configured, and adds the process with
+	// the specified pid into that cgroup.
+	Apply(pid int) error
+
+	// Destroy the cgroup set created by Apply.
+	Destroy() error
+
+	// GetPaths returns cgroup paths to save in a state file and to be
+	// used to restore the object later.
+	GetPaths() map[string]string
+
+	// GetStats returns the current cgroup stats across all subsystems.
+	GetStats() (*Stats, error)
+
+	// Freeze toggles the state of the container's freezer cgroup. When
+	// justContainer is true, only the container's own cgroup is frozen;
+	// sub-cgroups nested under it (e.g. ones an exec'd process created
+	// for itself) are left running.
+	Freeze(state configs.FreezerState, justContainer bool) error
+
+	// GetPids returns the PIDs inside the container's cgroup.
+	GetPids() ([]int, error)
+
+	// Set updates the existing container's cgroup with container's
+	// resource limits, without touching cgroup.procs.
+	Set(container *configs.Config) error
+}
@@ -1,42 +1,80 @@
 package fs
 
 import (
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"sync"
+	"syscall"
+	"time"
 
 	"github.com/docker/libcontainer/cgroups"
+	"github.com/docker/libcontainer/cgroups/fs2"
 	"github.com/docker/libcontainer/configs"
 )
 
 var (
 	subsystems = map[string]subsystem{
-		"devices":    &DevicesGroup{},
-		"memory":     &MemoryGroup{},
-		"cpu":        &CpuGroup{},
-		"cpuset":     &CpusetGroup{},
-		"cpuacct":    &CpuacctGroup{},
-		"blkio":      &BlkioGroup{},
-		"perf_event": &PerfEventGroup{},
-		"freezer":    &FreezerGroup{},
+		"devices":      &DevicesGroup{},
+		"memory":       &MemoryGroup{},
+		"cpu":          &CpuGroup{},
+		"cpuset":       &CpusetGroup{},
+		"cpuacct":      &CpuacctGroup{},
+		"blkio":        &BlkioGroup{},
+		"perf_event":   &PerfEventGroup{},
+		"freezer":      &FreezerGroup{},
+		"pids":         &PidsGroup{},
+		"hugetlb":      &HugetlbGroup{},
+		"net_cls":      &NetClsGroup{},
+		"net_prio":     &NetPrioGroup{},
+		"name=systemd": &NameGroup{GroupName: "name=systemd", Join: true},
 	}
 	CgroupProcesses = "cgroup.procs"
 )
 
+var _ cgroups.Manager = (*Manager)(nil)
+
 type subsystem interface {
 	// Returns the stats, as 'stats', corresponding to the cgroup under 'path'.
 	GetStats(path string, stats *cgroups.Stats) error
 	// Removes the cgroup represented by 'data'.
 	Remove(*data) error
-	// Creates and joins the cgroup represented by data.
-	Set(*data) error
+	// Create creates and joins the cgroup represented by data, without
+	// writing any resource limits into it.
+	Create(*data) error
+	// Apply writes r's resource limits into the cgroup directory at path.
+	// Manager.Apply calls Create then Apply against a freshly created
+	// directory; Manager.Set calls only Apply, against the directories
+	// recorded by an earlier Apply, so it can retune a running container
+	// without rejoining cgroup.procs.
+	Apply(path string, r *configs.Resources) error
 }
 
 type Manager struct {
 	Cgroups *configs.Cgroup
-	Paths   map[string]string
+
+	// Paths is normally populated by Apply as the set of per-controller
+	// cgroup directories it created and joined. If the caller pre-populates
+	// it instead, Apply treats it as an override: it skips creating any
+	// directories and just writes the pid into whatever's already there.
+	// This is how a non-root user reuses a cgroup tree delegated to them
+	// (systemd Delegate=yes, or a matching-uid ancestor), and how embedding
+	// runtimes (Nomad, cri-o) place a container inside a tree they manage.
+	Paths map[string]string
+
+	// Rootless, when true, means permission errors (EACCES, EPERM, EROFS)
+	// encountered while creating directories or writing cgroup files are
+	// swallowed rather than returned, since a non-root user can only expect
+	// to configure the parts of the hierarchy actually delegated to it.
+	Rootless bool
+
+	// v2, when non-nil, means this Manager was created against a unified
+	// (cgroup2) hierarchy and every method below delegates to it instead of
+	// using the per-controller v1 codepath.
+	v2 *fs2.Manager
 }
 
 // The absolute path to the root of the cgroup hierarchies.
@@ -52,12 +90,23 @@ func getCgroupRoot() (string, error) {
 		return cgroupRoot, nil
 	}
 
-	// we can pick any subsystem to find the root
-	cpuRoot, err := cgroups.FindCgroupMountpoint("cpu")
-	if err != nil {
-		return "", err
+	var root string
+	if cgroups.IsCgroup2UnifiedMode() {
+		// Under the unified hierarchy there is a single mountpoint, not one
+		// per controller, so there's no sibling directory to take Dir() of.
+		unifiedRoot, err := cgroups.FindCgroupMountpoint("")
+		if err != nil {
+			return "", err
+		}
+		root = unifiedRoot
+	} else {
+		// we can pick any subsystem to find the root
+		cpuRoot, err := cgroups.FindCgroupMountpoint("cpu")
+		if err != nil {
+			return "", err
+		}
+		root = filepath.Dir(cpuRoot)
 	}
-	root := filepath.Dir(cpuRoot)
 
 	if _, err := os.Stat(root); err != nil {
 		return "", err
@@ -68,18 +117,45 @@ func getCgroupRoot() (string, error) {
 }
 
 type data struct {
-	root   string
-	cgroup string
-	c      *configs.Cgroup
-	pid    int
+	root     string
+	cgroup   string
+	c        *configs.Cgroup
+	pid      int
+	rootless bool
 }
 
-func (m *Manager) Apply(pid int) error {
+func (m *Manager) Apply(pid int) (err error) {
 	if m.Cgroups == nil {
 		return nil
 	}
 
-	d, err := getCgroupData(m.Cgroups, pid)
+	if len(m.Paths) > 0 {
+		for _, path := range m.Paths {
+			if err := tryWriteFile(m.Rootless, path, CgroupProcesses, strconv.Itoa(pid)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if cgroups.IsCgroup2UnifiedMode() {
+		root, err := getCgroupRoot()
+		if err != nil {
+			return err
+		}
+		cgroup := m.Cgroups.Name
+		if m.Cgroups.Parent != "" {
+			cgroup = filepath.Join(m.Cgroups.Parent, cgroup)
+		}
+		m.v2 = fs2.NewManager(m.Cgroups, root, cgroup, m.Rootless)
+		if err := m.v2.Apply(pid); err != nil {
+			return err
+		}
+		m.Paths = m.v2.GetPaths()
+		return nil
+	}
+
+	d, err := getCgroupData(m.Cgroups, pid, m.Rootless)
 	if err != nil {
 		return err
 	}
@@ -90,16 +166,19 @@ func (m *Manager) Apply(pid int) error {
 			cgroups.RemovePaths(paths)
 		}
 	}()
+	var p string
 	for name, sys := range subsystems {
-		if err := sys.Set(d); err != nil {
+		if err = sys.Create(d); err != nil {
+			if m.Rootless && isIgnorableError(err) {
+				err = nil
+				continue
+			}
 			return err
 		}
-		// TODO: Apply should, ideally, be reentrant or be broken up into a separate
-		// create and join phase so that the cgroup hierarchy for a container can be
-		// created then join consists of writing the process pids to cgroup.procs
-		p, err := d.path(name)
+		p, err = d.path(name)
 		if err != nil {
 			if cgroups.IsNotFound(err) {
+				err = nil
 				continue
 			}
 			return err
@@ -108,6 +187,23 @@ func (m *Manager) Apply(pid int) error {
 	}
 	m.Paths = paths
 
+	err = m.applyResources(m.Cgroups.Resources)
+	return err
+}
+
+// applyResources writes r into every subsystem directory this Manager
+// already has a path for. It's the half of Apply that Set also needs, so
+// retuning a running container doesn't have to rejoin cgroup.procs.
+func (m *Manager) applyResources(r *configs.Resources) error {
+	for name, sys := range subsystems {
+		path, ok := m.Paths[name]
+		if !ok {
+			continue
+		}
+		if err := sys.Apply(path, r); err != nil && !(m.Rootless && isIgnorableError(err)) {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -122,17 +218,36 @@ func (m *Manager) GetPaths() map[string]string {
 // Symmetrical public function to update device based cgroups.  Also available
 // in the systemd implementation.
 func ApplyDevices(c *configs.Cgroup, pid int) error {
-	d, err := getCgroupData(c, pid)
+	return createAndApply(subsystems["devices"], "devices", c, pid)
+}
+
+// ApplyCpuset is ApplyDevices' cpuset counterpart: the systemd implementation
+// doesn't expose cpuset.cpus/cpuset.mems as unit properties, so it falls
+// back to this to join and configure the cpuset cgroup directly.
+func ApplyCpuset(c *configs.Cgroup, pid int) error {
+	return createAndApply(subsystems["cpuset"], "cpuset", c, pid)
+}
+
+func createAndApply(sys subsystem, name string, c *configs.Cgroup, pid int) error {
+	d, err := getCgroupData(c, pid, false)
 	if err != nil {
 		return err
 	}
-
-	devices := subsystems["devices"]
-
-	return devices.Set(d)
+	if err := sys.Create(d); err != nil {
+		return err
+	}
+	path, err := d.path(name)
+	if err != nil {
+		return err
+	}
+	return sys.Apply(path, c.Resources)
 }
 
 func (m *Manager) GetStats() (*cgroups.Stats, error) {
+	if m.v2 != nil {
+		return m.v2.GetStats()
+	}
+
 	stats := cgroups.NewStats()
 	for name, path := range m.Paths {
 		sys, ok := subsystems[name]
@@ -147,34 +262,156 @@ func (m *Manager) GetStats() (*cgroups.Stats, error) {
 	return stats, nil
 }
 
-// Freeze toggles the container's freezer cgroup depending on the state
-// provided
-func (m *Manager) Freeze(state configs.FreezerState) error {
-	d, err := getCgroupData(m.Cgroups, 0)
+// Freeze toggles the state of the container's freezer cgroup and blocks
+// until freezer.state reads back the requested value. The kernel reports
+// FROZEN asynchronously -- a cgroup can sit in the transitional FREEZING
+// state while tasks are still being stopped -- so Freeze polls rather than
+// returning as soon as the write succeeds. When justContainer is true, only
+// the container's own cgroup is touched; any sub-cgroups nested under it
+// (e.g. ones created by an exec'd process with its own freezer group) are
+// left running.
+func (m *Manager) Freeze(state configs.FreezerState, justContainer bool) error {
+	if m.v2 != nil {
+		return m.v2.Freeze(state, justContainer)
+	}
+
+	freezerPath, err := m.path("freezer")
 	if err != nil {
 		return err
 	}
 
+	dirs := []string{freezerPath}
+	if !justContainer {
+		if dirs, err = subtreeDirs(freezerPath); err != nil {
+			return err
+		}
+	}
+
 	prevState := m.Cgroups.Freezer
 	m.Cgroups.Freezer = state
+	want := freezerStateFile(state)
+	for _, dir := range dirs {
+		if err := tryWriteFile(m.Rootless, dir, "freezer.state", want); err != nil {
+			m.Cgroups.Freezer = prevState
+			return err
+		}
+	}
+	for _, dir := range dirs {
+		if err := waitFreezerState(dir, want); err != nil {
+			m.Cgroups.Freezer = prevState
+			return err
+		}
+	}
+
+	return nil
+}
 
-	freezer := subsystems["freezer"]
-	err = freezer.Set(d)
+// freezeTimeout and freezePollInterval bound how long Freeze waits for
+// freezer.state to read back the value it just wrote.
+const (
+	freezeTimeout      = 10 * time.Second
+	freezePollInterval = 10 * time.Millisecond
+)
+
+// waitFreezerState polls dir's freezer.state until it reads back want,
+// since writing FROZEN only requests the transition -- the cgroup can sit
+// in the transitional FREEZING state while the kernel is still stopping
+// tasks, and callers like Signal depend on the freeze having actually
+// completed before they enumerate cgroup.procs.
+func waitFreezerState(dir, want string) error {
+	deadline := time.Now().Add(freezeTimeout)
+	for {
+		got, err := readFile(dir, "freezer.state")
+		if err != nil {
+			return err
+		}
+		if strings.TrimSpace(got) == want {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("fs: timed out waiting for %s/freezer.state to read %q", dir, want)
+		}
+		time.Sleep(freezePollInterval)
+	}
+}
+
+// Signal freezes the container's freezer cgroup (and, if includeSubcgroups
+// is set, every cgroup nested under it), sends sig to every pid found in
+// their cgroup.procs, then thaws everything back out. Freezing first closes
+// the race a plain GetPids-then-kill loop has against a process that forks
+// or reparents between the read and the kill, which matters most for
+// containers running an init system.
+func (m *Manager) Signal(sig syscall.Signal, includeSubcgroups bool) error {
+	if m.v2 != nil {
+		return m.v2.Signal(sig, includeSubcgroups)
+	}
+
+	freezerPath, err := m.path("freezer")
 	if err != nil {
-		m.Cgroups.Freezer = prevState
 		return err
 	}
 
+	if err := m.Freeze(configs.Frozen, !includeSubcgroups); err != nil {
+		return err
+	}
+	defer m.Freeze(configs.Thawed, !includeSubcgroups)
+
+	dirs := []string{freezerPath}
+	if includeSubcgroups {
+		if dirs, err = subtreeDirs(freezerPath); err != nil {
+			return err
+		}
+	}
+
+	for _, dir := range dirs {
+		pids, err := cgroups.ReadProcsFile(dir)
+		if err != nil {
+			return err
+		}
+		for _, pid := range pids {
+			if err := syscall.Kill(pid, sig); err != nil && err != syscall.ESRCH {
+				return err
+			}
+		}
+	}
+
 	return nil
 }
 
-func (m *Manager) GetPids() ([]int, error) {
-	d, err := getCgroupData(m.Cgroups, 0)
+// freezerStateFile returns the value to write to freezer.state for state.
+func freezerStateFile(state configs.FreezerState) string {
+	switch state {
+	case configs.Frozen:
+		return "FROZEN"
+	default:
+		return "THAWED"
+	}
+}
+
+// subtreeDirs returns path and every directory nested under it.
+func subtreeDirs(path string) ([]string, error) {
+	var dirs []string
+	err := filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			dirs = append(dirs, p)
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
+	return dirs, nil
+}
+
+func (m *Manager) GetPids() ([]int, error) {
+	if m.v2 != nil {
+		return m.v2.GetPids()
+	}
 
-	dir, err := d.path("devices")
+	dir, err := m.path("devices")
 	if err != nil {
 		return nil, err
 	}
@@ -182,7 +419,35 @@ func (m *Manager) GetPids() ([]int, error) {
 	return cgroups.ReadProcsFile(dir)
 }
 
-func getCgroupData(c *configs.Cgroup, pid int) (*data, error) {
+// path resolves the directory for subsystem, preferring an entry already
+// present in m.Paths (populated either by a prior Apply or, for a Manager
+// embedded in another backend like cgroups/systemd, supplied directly)
+// over recomputing it from m.Cgroups.
+func (m *Manager) path(subsystem string) (string, error) {
+	if p, ok := m.Paths[subsystem]; ok {
+		return p, nil
+	}
+
+	d, err := getCgroupData(m.Cgroups, 0, m.Rootless)
+	if err != nil {
+		return "", err
+	}
+	return d.path(subsystem)
+}
+
+// Set updates the resource limits of an already-running container from
+// container's Cgroups config, writing straight into the paths this Manager
+// recorded on its prior Apply and never touching cgroup.procs.
+func (m *Manager) Set(container *configs.Config) error {
+	if m.v2 != nil {
+		return m.v2.Set(container.Cgroups)
+	}
+
+	m.Cgroups = container.Cgroups
+	return m.applyResources(container.Cgroups.Resources)
+}
+
+func getCgroupData(c *configs.Cgroup, pid int, rootless bool) (*data, error) {
 	root, err := getCgroupRoot()
 	if err != nil {
 		return nil, err
@@ -194,10 +459,11 @@ func getCgroupData(c *configs.Cgroup, pid int) (*data, error) {
 	}
 
 	return &data{
-		root:   root,
-		cgroup: cgroup,
-		c:      c,
-		pid:    pid,
+		root:     root,
+		cgroup:   cgroup,
+		c:        c,
+		pid:      pid,
+		rootless: rootless,
 	}, nil
 }
 
@@ -239,14 +505,30 @@ func (raw *data) join(subsystem string) (string, error) {
 		return "", err
 	}
 	if err := os.MkdirAll(path, 0755); err != nil && !os.IsExist(err) {
+		if raw.rootless && isIgnorableError(err) {
+			return path, nil
+		}
 		return "", err
 	}
-	if err := writeFile(path, CgroupProcesses, strconv.Itoa(raw.pid)); err != nil {
+	if err := tryWriteFile(raw.rootless, path, CgroupProcesses, strconv.Itoa(raw.pid)); err != nil {
 		return "", err
 	}
 	return path, nil
 }
 
+// tryWriteFile writes a single knob and, when rootless is true, swallows
+// the error if it's the kind a non-root user attempting to reuse a
+// delegated cgroup is expected to hit.
+func tryWriteFile(rootless bool, dir, file, data string) error {
+	if err := writeFile(dir, file, data); err != nil {
+		if rootless && isIgnorableError(err) {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
 func writeFile(dir, file, data string) error {
 	return ioutil.WriteFile(filepath.Join(dir, file), []byte(data), 0700)
 }
@@ -265,3 +547,23 @@ func removePath(p string, err error) error {
 	}
 	return nil
 }
+
+// isIgnorableError reports whether err is a permission-denied error
+// (EACCES, EPERM, or EROFS) of the kind a rootless Manager should swallow
+// rather than fail on, since a non-root user can only ever expect to
+// configure the parts of a delegated cgroup tree it actually has rights to.
+func isIgnorableError(err error) bool {
+	switch e := err.(type) {
+	case *os.PathError:
+		err = e.Err
+	case *os.LinkError:
+		err = e.Err
+	case *os.SyscallError:
+		err = e.Err
+	}
+	errno, ok := err.(syscall.Errno)
+	if !ok {
+		return false
+	}
+	return errno == syscall.EACCES || errno == syscall.EPERM || errno == syscall.EROFS
+}
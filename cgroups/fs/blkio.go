@@ -0,0 +1,62 @@
+package fs
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/docker/libcontainer/cgroups"
+	"github.com/docker/libcontainer/configs"
+)
+
+type BlkioGroup struct {
+}
+
+func (s *BlkioGroup) Create(d *data) error {
+	_, err := d.join("blkio")
+	if err != nil {
+		if cgroups.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *BlkioGroup) Apply(path string, r *configs.Resources) error {
+	if path == "" || r.BlkioWeight == 0 {
+		return nil
+	}
+	return writeFile(path, "blkio.weight", strconv.FormatUint(uint64(r.BlkioWeight), 10))
+}
+
+func (s *BlkioGroup) Remove(d *data) error {
+	return removePath(d.path("blkio"))
+}
+
+func (s *BlkioGroup) GetStats(path string, stats *cgroups.Stats) error {
+	if !cgroups.PathExists(path) {
+		return nil
+	}
+
+	raw, err := readFile(path, "blkio.throttle.io_service_bytes")
+	if err != nil {
+		return err
+	}
+
+	for _, line := range strings.Split(strings.TrimSpace(raw), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 || fields[1] != "Total" {
+			continue
+		}
+		value, err := strconv.ParseUint(fields[2], 10, 64)
+		if err != nil {
+			continue
+		}
+		stats.BlkioStats.IoServiceBytesRecursive = append(stats.BlkioStats.IoServiceBytesRecursive, cgroups.BlkioStatEntry{
+			Device: fields[0],
+			Value:  value,
+		})
+	}
+
+	return nil
+}
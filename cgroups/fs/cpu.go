@@ -0,0 +1,61 @@
+package fs
+
+import (
+	"strconv"
+
+	"github.com/docker/libcontainer/cgroups"
+	"github.com/docker/libcontainer/configs"
+)
+
+type CpuGroup struct {
+}
+
+func (s *CpuGroup) Create(d *data) error {
+	_, err := d.join("cpu")
+	if err != nil {
+		if cgroups.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *CpuGroup) Apply(path string, r *configs.Resources) error {
+	if path == "" {
+		return nil
+	}
+
+	if r.CpuShares != 0 {
+		if err := writeFile(path, "cpu.shares", strconv.FormatUint(r.CpuShares, 10)); err != nil {
+			return err
+		}
+	}
+	if r.CpuPeriod != 0 {
+		if err := writeFile(path, "cpu.cfs_period_us", strconv.FormatUint(r.CpuPeriod, 10)); err != nil {
+			return err
+		}
+	}
+	if r.CpuQuota != 0 {
+		if err := writeFile(path, "cpu.cfs_quota_us", strconv.FormatInt(r.CpuQuota, 10)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *CpuGroup) Remove(d *data) error {
+	return removePath(d.path("cpu"))
+}
+
+func (s *CpuGroup) GetStats(path string, stats *cgroups.Stats) error {
+	raw, err := getCgroupParamKeyValue(path, "cpu.stat")
+	if err != nil {
+		return err
+	}
+	stats.CpuStats.ThrottlingData.Periods = raw["nr_periods"]
+	stats.CpuStats.ThrottlingData.ThrottledPeriods = raw["nr_throttled"]
+	stats.CpuStats.ThrottlingData.ThrottledTime = raw["throttled_time"]
+	return nil
+}
@@ -0,0 +1,56 @@
+package fs
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/docker/libcontainer/cgroups"
+	"github.com/docker/libcontainer/configs"
+)
+
+// CpuacctGroup reports per-container CPU usage. It has no configs.Resources
+// knobs of its own -- cpu.go covers cpu.shares/cfs_quota -- so Apply is a
+// no-op.
+type CpuacctGroup struct {
+}
+
+func (s *CpuacctGroup) Create(d *data) error {
+	_, err := d.join("cpuacct")
+	if err != nil {
+		if cgroups.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *CpuacctGroup) Apply(path string, r *configs.Resources) error {
+	return nil
+}
+
+func (s *CpuacctGroup) Remove(d *data) error {
+	return removePath(d.path("cpuacct"))
+}
+
+func (s *CpuacctGroup) GetStats(path string, stats *cgroups.Stats) error {
+	totalUsage, err := getCgroupParamUint(path, "cpuacct.usage")
+	if err != nil {
+		return err
+	}
+	stats.CpuStats.CpuUsage.TotalUsage = totalUsage
+
+	percpu, err := readFile(path, "cpuacct.usage_percpu")
+	if err != nil {
+		return err
+	}
+	for _, v := range strings.Fields(percpu) {
+		u, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return err
+		}
+		stats.CpuStats.CpuUsage.PercpuUsage = append(stats.CpuStats.CpuUsage.PercpuUsage, u)
+	}
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package fs
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/libcontainer/cgroups"
+	"github.com/docker/libcontainer/configs"
+)
+
+// CpusetGroup manages the cpuset controller. A freshly created cpuset
+// directory starts out with empty cpuset.cpus/cpuset.mems, and the kernel
+// refuses to let a pid join a cpuset with no cpus/mems configured, so
+// Create seeds both files from the nearest ancestor that already has them
+// before joining.
+type CpusetGroup struct {
+}
+
+func (s *CpusetGroup) Create(d *data) error {
+	path, err := d.path("cpuset")
+	if err != nil {
+		if cgroups.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	if err := s.ensureCpusAndMems(path, d.rootless); err != nil {
+		return err
+	}
+
+	_, err = d.join("cpuset")
+	if err != nil {
+		if cgroups.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// ensureCpusAndMems copies cpuset.cpus/cpuset.mems down from the parent
+// directory into path if path doesn't already have them set, recursing up
+// as many levels as necessary to find a non-empty value.
+func (s *CpusetGroup) ensureCpusAndMems(path string, rootless bool) error {
+	if err := os.MkdirAll(path, 0755); err != nil && !os.IsExist(err) {
+		if rootless && isIgnorableError(err) {
+			return nil
+		}
+		return err
+	}
+
+	cpus, err := readFile(path, "cpuset.cpus")
+	if err != nil {
+		return err
+	}
+	mems, err := readFile(path, "cpuset.mems")
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(cpus) != "" && strings.TrimSpace(mems) != "" {
+		return nil
+	}
+
+	parent := filepath.Dir(path)
+	if parent == path {
+		return nil
+	}
+	if err := s.ensureCpusAndMems(parent, rootless); err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(cpus) == "" {
+		parentCpus, err := readFile(parent, "cpuset.cpus")
+		if err != nil {
+			return err
+		}
+		if err := writeFile(path, "cpuset.cpus", parentCpus); err != nil && !(rootless && isIgnorableError(err)) {
+			return err
+		}
+	}
+	if strings.TrimSpace(mems) == "" {
+		parentMems, err := readFile(parent, "cpuset.mems")
+		if err != nil {
+			return err
+		}
+		if err := writeFile(path, "cpuset.mems", parentMems); err != nil && !(rootless && isIgnorableError(err)) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *CpusetGroup) Apply(path string, r *configs.Resources) error {
+	if path == "" {
+		return nil
+	}
+	if r.CpusetCpus != "" {
+		if err := writeFile(path, "cpuset.cpus", r.CpusetCpus); err != nil {
+			return err
+		}
+	}
+	if r.CpusetMems != "" {
+		if err := writeFile(path, "cpuset.mems", r.CpusetMems); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *CpusetGroup) Remove(d *data) error {
+	return removePath(d.path("cpuset"))
+}
+
+func (s *CpusetGroup) GetStats(path string, stats *cgroups.Stats) error {
+	return nil
+}
@@ -0,0 +1,49 @@
+package fs
+
+import (
+	"github.com/docker/libcontainer/cgroups"
+	"github.com/docker/libcontainer/configs"
+)
+
+// DevicesGroup writes the devices.allow/devices.deny whitelist on v1 hosts.
+// v2 has no devices controller; cgroups/fs2's setDevices loads no eBPF
+// program and rejects a non-empty device list instead.
+type DevicesGroup struct {
+}
+
+func (s *DevicesGroup) Create(d *data) error {
+	_, err := d.join("devices")
+	if err != nil {
+		if cgroups.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *DevicesGroup) Apply(path string, r *configs.Resources) error {
+	if path == "" {
+		return nil
+	}
+
+	for _, dev := range r.Devices {
+		file := "devices.deny"
+		if dev.Allow {
+			file = "devices.allow"
+		}
+		if err := writeFile(path, file, dev.CgroupString()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *DevicesGroup) Remove(d *data) error {
+	return removePath(d.path("devices"))
+}
+
+func (s *DevicesGroup) GetStats(path string, stats *cgroups.Stats) error {
+	return nil
+}
@@ -0,0 +1,107 @@
+package fs
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/docker/libcontainer/cgroups"
+	"github.com/docker/libcontainer/configs"
+)
+
+// hugePageSizes lists the "<size>" component of each hugetlb.<size>.* file
+// this host's kernel exposes, e.g. "2MB" or "1GB". It's discovered once at
+// package init by scanning /sys/kernel/mm/hugepages rather than hard-coded,
+// since which sizes exist depends on the architecture and boot parameters.
+var hugePageSizes = discoverHugePageSizes()
+
+func discoverHugePageSizes() []string {
+	dirs, err := ioutil.ReadDir("/sys/kernel/mm/hugepages")
+	if err != nil {
+		return nil
+	}
+
+	var sizes []string
+	for _, dir := range dirs {
+		// dir.Name() looks like "hugepages-2048kB".
+		name := strings.TrimSuffix(strings.TrimPrefix(dir.Name(), "hugepages-"), "kB")
+		kb, err := strconv.ParseUint(name, 10, 64)
+		if err != nil {
+			continue
+		}
+		sizes = append(sizes, humanHugePageSize(kb))
+	}
+	return sizes
+}
+
+func humanHugePageSize(kb uint64) string {
+	switch {
+	case kb != 0 && kb%(1024*1024) == 0:
+		return strconv.FormatUint(kb/(1024*1024), 10) + "GB"
+	case kb != 0 && kb%1024 == 0:
+		return strconv.FormatUint(kb/1024, 10) + "MB"
+	default:
+		return strconv.FormatUint(kb, 10) + "KB"
+	}
+}
+
+type HugetlbGroup struct {
+}
+
+func (s *HugetlbGroup) Create(d *data) error {
+	_, err := d.join("hugetlb")
+	if err != nil {
+		if cgroups.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *HugetlbGroup) Apply(path string, r *configs.Resources) error {
+	if path == "" {
+		return nil
+	}
+
+	for _, hugetlb := range r.HugetlbLimit {
+		if err := writeFile(path, fmt.Sprintf("hugetlb.%s.limit_in_bytes", hugetlb.Pagesize), strconv.FormatUint(hugetlb.Limit, 10)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *HugetlbGroup) Remove(d *data) error {
+	return removePath(d.path("hugetlb"))
+}
+
+func (s *HugetlbGroup) GetStats(path string, stats *cgroups.Stats) error {
+	if stats.HugetlbStats == nil {
+		stats.HugetlbStats = make(map[string]cgroups.HugetlbStats)
+	}
+
+	for _, pageSize := range hugePageSizes {
+		var hugetlbStats cgroups.HugetlbStats
+
+		usage := fmt.Sprintf("hugetlb.%s.usage_in_bytes", pageSize)
+		value, err := getCgroupParamUint(path, usage)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s - %s", usage, err)
+		}
+		hugetlbStats.Usage = value
+
+		maxUsage := fmt.Sprintf("hugetlb.%s.max_usage_in_bytes", pageSize)
+		value, err = getCgroupParamUint(path, maxUsage)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s - %s", maxUsage, err)
+		}
+		hugetlbStats.MaxUsage = value
+
+		stats.HugetlbStats[pageSize] = hugetlbStats
+	}
+
+	return nil
+}
@@ -0,0 +1,22 @@
+package fs
+
+import "testing"
+
+func TestHumanHugePageSize(t *testing.T) {
+	cases := []struct {
+		kb   uint64
+		want string
+	}{
+		{4, "4KB"},
+		{2048, "2MB"},
+		{1048576, "1GB"},
+		{1024, "1MB"},
+		{1536, "1536KB"},
+	}
+
+	for _, c := range cases {
+		if got := humanHugePageSize(c.kb); got != c.want {
+			t.Errorf("humanHugePageSize(%d) = %q, want %q", c.kb, got, c.want)
+		}
+	}
+}
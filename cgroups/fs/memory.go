@@ -0,0 +1,70 @@
+package fs
+
+import (
+	"strconv"
+
+	"github.com/docker/libcontainer/cgroups"
+	"github.com/docker/libcontainer/configs"
+)
+
+type MemoryGroup struct {
+}
+
+func (s *MemoryGroup) Create(d *data) error {
+	_, err := d.join("memory")
+	if err != nil {
+		if cgroups.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *MemoryGroup) Apply(path string, r *configs.Resources) error {
+	if path == "" {
+		return nil
+	}
+
+	// memory.memsw.limit_in_bytes can't be set below memory.limit_in_bytes,
+	// so raise the swap ceiling first only when the memory limit is being
+	// raised; otherwise lower the memory limit first.
+	swapFirst := false
+	if r.Memory != 0 && r.MemorySwap != 0 {
+		current, err := getCgroupParamUint(path, "memory.limit_in_bytes")
+		if err != nil {
+			return err
+		}
+		swapFirst = uint64(r.Memory) > current
+	}
+	if r.MemorySwap != 0 && swapFirst {
+		if err := writeFile(path, "memory.memsw.limit_in_bytes", strconv.FormatInt(r.MemorySwap, 10)); err != nil {
+			return err
+		}
+	}
+	if r.Memory != 0 {
+		if err := writeFile(path, "memory.limit_in_bytes", strconv.FormatInt(r.Memory, 10)); err != nil {
+			return err
+		}
+	}
+	if r.MemorySwap != 0 && !swapFirst {
+		if err := writeFile(path, "memory.memsw.limit_in_bytes", strconv.FormatInt(r.MemorySwap, 10)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *MemoryGroup) Remove(d *data) error {
+	return removePath(d.path("memory"))
+}
+
+func (s *MemoryGroup) GetStats(path string, stats *cgroups.Stats) error {
+	raw, err := getCgroupParamKeyValue(path, "memory.stat")
+	if err != nil {
+		return err
+	}
+	stats.MemoryStats.Stats = raw
+	return nil
+}
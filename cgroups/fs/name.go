@@ -0,0 +1,37 @@
+package fs
+
+import (
+	"github.com/docker/libcontainer/cgroups"
+	"github.com/docker/libcontainer/configs"
+)
+
+// NameGroup handles a purely named hierarchy, e.g. the "name=systemd"
+// cgroup systemd maintains for its own bookkeeping. There's nothing to
+// configure on one of these: when Join is true the pid is placed into it,
+// otherwise it's left untouched.
+type NameGroup struct {
+	GroupName string
+	Join      bool
+}
+
+func (s *NameGroup) Create(d *data) error {
+	if s.Join {
+		// Ignore errors if the named hierarchy isn't mounted.
+		if _, err := d.join(s.GroupName); err != nil && !cgroups.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *NameGroup) Apply(path string, r *configs.Resources) error {
+	return nil
+}
+
+func (s *NameGroup) Remove(d *data) error {
+	return removePath(d.path(s.GroupName))
+}
+
+func (s *NameGroup) GetStats(path string, stats *cgroups.Stats) error {
+	return nil
+}
@@ -0,0 +1,37 @@
+package fs
+
+import (
+	"strconv"
+
+	"github.com/docker/libcontainer/cgroups"
+	"github.com/docker/libcontainer/configs"
+)
+
+type NetClsGroup struct {
+}
+
+func (s *NetClsGroup) Create(d *data) error {
+	_, err := d.join("net_cls")
+	if err != nil {
+		if cgroups.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *NetClsGroup) Apply(path string, r *configs.Resources) error {
+	if path == "" || r.NetClsClassid == 0 {
+		return nil
+	}
+	return writeFile(path, "net_cls.classid", strconv.FormatUint(uint64(r.NetClsClassid), 10))
+}
+
+func (s *NetClsGroup) Remove(d *data) error {
+	return removePath(d.path("net_cls"))
+}
+
+func (s *NetClsGroup) GetStats(path string, stats *cgroups.Stats) error {
+	return nil
+}
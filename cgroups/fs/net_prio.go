@@ -0,0 +1,44 @@
+package fs
+
+import (
+	"fmt"
+
+	"github.com/docker/libcontainer/cgroups"
+	"github.com/docker/libcontainer/configs"
+)
+
+type NetPrioGroup struct {
+}
+
+func (s *NetPrioGroup) Create(d *data) error {
+	_, err := d.join("net_prio")
+	if err != nil {
+		if cgroups.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *NetPrioGroup) Apply(path string, r *configs.Resources) error {
+	if path == "" {
+		return nil
+	}
+
+	for _, prioMap := range r.NetPrioIfpriomap {
+		if err := writeFile(path, "net_prio.ifpriomap", fmt.Sprintf("%s %d", prioMap.Interface, prioMap.Priority)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *NetPrioGroup) Remove(d *data) error {
+	return removePath(d.path("net_prio"))
+}
+
+func (s *NetPrioGroup) GetStats(path string, stats *cgroups.Stats) error {
+	return nil
+}
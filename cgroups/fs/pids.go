@@ -0,0 +1,64 @@
+package fs
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/docker/libcontainer/cgroups"
+	"github.com/docker/libcontainer/configs"
+)
+
+type PidsGroup struct {
+}
+
+func (s *PidsGroup) Create(d *data) error {
+	_, err := d.join("pids")
+	if err != nil {
+		// Per the cgroups tree construction, not all containers will have a pids cgroup.
+		if cgroups.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *PidsGroup) Apply(path string, r *configs.Resources) error {
+	if path == "" || r.PidsLimit == 0 {
+		return nil
+	}
+
+	// "max" is the fallback value.
+	limit := "max"
+	if r.PidsLimit > 0 {
+		limit = strconv.FormatInt(r.PidsLimit, 10)
+	}
+
+	return writeFile(path, "pids.max", limit)
+}
+
+func (s *PidsGroup) Remove(d *data) error {
+	return removePath(d.path("pids"))
+}
+
+func (s *PidsGroup) GetStats(path string, stats *cgroups.Stats) error {
+	if !cgroups.PathExists(path) {
+		return nil
+	}
+
+	current, err := getCgroupParamUint(path, "pids.current")
+	if err != nil {
+		return fmt.Errorf("failed to parse pids.current - %s", err)
+	}
+
+	// pids.max returns "max" when no limit is set, which getCgroupParamUint
+	// turns into math.MaxUint64.
+	max, err := getCgroupParamUint(path, "pids.max")
+	if err != nil {
+		return fmt.Errorf("failed to parse pids.max - %s", err)
+	}
+
+	stats.PidsStats.Current = current
+	stats.PidsStats.Limit = max
+	return nil
+}
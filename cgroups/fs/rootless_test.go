@@ -0,0 +1,33 @@
+package fs
+
+import (
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestIsIgnorableError(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"plain EACCES", syscall.EACCES, true},
+		{"plain EPERM", syscall.EPERM, true},
+		{"plain EROFS", syscall.EROFS, true},
+		{"plain ENOENT", syscall.ENOENT, false},
+		{"path error wrapping EACCES", &os.PathError{Op: "open", Path: "/x", Err: syscall.EACCES}, true},
+		{"path error wrapping ENOENT", &os.PathError{Op: "open", Path: "/x", Err: syscall.ENOENT}, false},
+		{"link error wrapping EPERM", &os.LinkError{Op: "rename", Old: "/a", New: "/b", Err: syscall.EPERM}, true},
+		{"syscall error wrapping EROFS", os.NewSyscallError("write", syscall.EROFS), true},
+		{"non-errno error", os.ErrClosed, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := isIgnorableError(c.err); got != c.want {
+				t.Errorf("isIgnorableError(%v) = %v, want %v", c.err, got, c.want)
+			}
+		})
+	}
+}
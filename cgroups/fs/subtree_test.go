@@ -0,0 +1,49 @@
+package fs
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func TestSubtreeDirs(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{
+		".",
+		"child-a",
+		"child-b",
+		filepath.Join("child-b", "grandchild"),
+	} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A plain file alongside the directories shouldn't show up in the result.
+	if err := os.WriteFile(filepath.Join(root, "not-a-dir"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := subtreeDirs(root)
+	if err != nil {
+		t.Fatalf("subtreeDirs: %v", err)
+	}
+	sort.Strings(got)
+
+	want := []string{
+		root,
+		filepath.Join(root, "child-a"),
+		filepath.Join(root, "child-b"),
+		filepath.Join(root, "child-b", "grandchild"),
+	}
+	sort.Strings(want)
+
+	if len(got) != len(want) {
+		t.Fatalf("subtreeDirs(%q) = %v, want %v", root, got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("subtreeDirs(%q)[%d] = %q, want %q", root, i, got[i], want[i])
+		}
+	}
+}
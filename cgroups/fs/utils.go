@@ -0,0 +1,52 @@
+package fs
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// getCgroupParamUint reads a single numeric value out of a cgroup file,
+// treating the literal "max" (used by several v1 controllers, e.g.
+// pids.max, to mean "unlimited") as math.MaxUint64.
+func getCgroupParamUint(dir, file string) (uint64, error) {
+	contents, err := readFile(dir, file)
+	if err != nil {
+		return 0, err
+	}
+
+	contents = strings.TrimSpace(contents)
+	if contents == "max" {
+		return math.MaxUint64, nil
+	}
+
+	res, err := strconv.ParseUint(contents, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("unable to parse %q as a uint from cgroup file %q", contents, file)
+	}
+	return res, nil
+}
+
+// getCgroupParamKeyValue parses the "key value\n"-per-line format shared by
+// memory.stat, cpu.stat, and friends into a map.
+func getCgroupParamKeyValue(dir, file string) (map[string]uint64, error) {
+	contents, err := readFile(dir, file)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]uint64)
+	for _, line := range strings.Split(strings.TrimSpace(contents), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		v, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		out[fields[0]] = v
+	}
+	return out, nil
+}
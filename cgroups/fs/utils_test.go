@@ -0,0 +1,30 @@
+package fs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGetCgroupParamKeyValue(t *testing.T) {
+	dir := t.TempDir()
+	contents := "cache 1024\nrss 2048\nmalformed-line\nswap 4096\n"
+	if err := os.WriteFile(filepath.Join(dir, "memory.stat"), []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := getCgroupParamKeyValue(dir, "memory.stat")
+	if err != nil {
+		t.Fatalf("getCgroupParamKeyValue: %v", err)
+	}
+
+	want := map[string]uint64{"cache": 1024, "rss": 2048, "swap": 4096}
+	if len(got) != len(want) {
+		t.Fatalf("getCgroupParamKeyValue = %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("getCgroupParamKeyValue[%q] = %d, want %d", k, got[k], v)
+		}
+	}
+}
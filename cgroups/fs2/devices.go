@@ -0,0 +1,22 @@
+package fs2
+
+import (
+	"errors"
+
+	"github.com/docker/libcontainer/configs"
+)
+
+// ErrDevicesUnsupported is returned by setDevices when a container specifies
+// a device whitelist on a cgroup-v2 host. v2 dropped the devices controller
+// entirely; the only replacement is attaching a BPF_CGROUP_DEVICE program
+// via a cgroups/ebpf loader, which doesn't exist in this tree yet. Rather
+// than call into an undefined package, surface that plainly instead of
+// silently granting (or silently failing to restrict) device access.
+var ErrDevicesUnsupported = errors.New("fs2: device whitelists are not yet supported on cgroup v2 (requires a BPF_CGROUP_DEVICE loader)")
+
+func (m *Manager) setDevices(r *configs.Resources) error {
+	if len(r.Devices) == 0 {
+		return nil
+	}
+	return ErrDevicesUnsupported
+}
@@ -0,0 +1,263 @@
+// Package fs2 manages a container's cgroup on the unified (v2) cgroup
+// hierarchy, on behalf of cgroups/fs and cgroups/systemd. Unlike the v1 implementation in cgroups/fs, which keeps one
+// directory per controller under the root of each controller's own
+// mountpoint, v2 exposes every controller as a set of files inside a single
+// directory, so a Manager here only ever has one path to track.
+package fs2
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/docker/libcontainer/cgroups"
+	"github.com/docker/libcontainer/configs"
+)
+
+// Manager manages a cgroup tree rooted at Path in a unified (cgroup2)
+// hierarchy. It's used internally by cgroups/fs.Manager to back a Manager
+// created against the unified hierarchy. Its methods mirror cgroups.Manager
+// except that Set takes a *configs.Cgroup directly rather than the full
+// container config, so it does not itself satisfy cgroups.Manager.
+type Manager struct {
+	Cgroups *configs.Cgroup
+	// Path is the absolute path to this container's cgroup directory,
+	// e.g. /sys/fs/cgroup/machine.slice/foo.
+	Path string
+
+	// Rootless, when true, means permission errors encountered while
+	// creating the directory, enabling controllers, or writing resource
+	// knobs are swallowed rather than returned, mirroring cgroups/fs's
+	// Rootless support for the v1 hierarchy.
+	Rootless bool
+}
+
+// NewManager returns a Manager for the unified hierarchy rooted at path.
+// path is resolved from root and cg the same way fs.getCgroupData resolves
+// per-controller paths in the v1 implementation.
+func NewManager(cg *configs.Cgroup, root, path string, rootless bool) *Manager {
+	return &Manager{
+		Cgroups:  cg,
+		Path:     filepath.Join(root, path),
+		Rootless: rootless,
+	}
+}
+
+func (m *Manager) Apply(pid int) error {
+	if m.Cgroups == nil {
+		return nil
+	}
+
+	if err := os.MkdirAll(m.Path, 0755); err != nil && !os.IsExist(err) {
+		if !(m.Rootless && isIgnorableError(err)) {
+			return err
+		}
+	}
+
+	if err := enableControllers(m.Path, m.Rootless); err != nil {
+		cgroups.RemovePaths(map[string]string{"": m.Path})
+		return err
+	}
+
+	if err := writeFile(m.Path, "cgroup.procs", strconv.Itoa(pid)); err != nil && !(m.Rootless && isIgnorableError(err)) {
+		cgroups.RemovePaths(map[string]string{"": m.Path})
+		return err
+	}
+
+	if err := m.setResources(m.Cgroups.Resources); err != nil && !(m.Rootless && isIgnorableError(err)) {
+		cgroups.RemovePaths(map[string]string{"": m.Path})
+		return err
+	}
+
+	return nil
+}
+
+func (m *Manager) Destroy() error {
+	return os.RemoveAll(m.Path)
+}
+
+// GetPaths returns the single unified path under the empty controller name,
+// matching the convention that callers range over the result and treat each
+// entry as "controller name -> cgroup dir".
+func (m *Manager) GetPaths() map[string]string {
+	return map[string]string{"": m.Path}
+}
+
+func (m *Manager) GetStats() (*cgroups.Stats, error) {
+	stats := cgroups.NewStats()
+	if err := statMemory(m.Path, stats); err != nil {
+		return nil, err
+	}
+	if err := statIO(m.Path, stats); err != nil {
+		return nil, err
+	}
+	if err := statCPU(m.Path, stats); err != nil {
+		return nil, err
+	}
+	if err := statPids(m.Path, stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+func (m *Manager) GetPids() ([]int, error) {
+	return cgroups.ReadProcsFile(m.Path)
+}
+
+// Set updates the resource limits of the already-applied cgroup at m.Path.
+// Unlike Apply, it never touches cgroup.procs.
+func (m *Manager) Set(cg *configs.Cgroup) error {
+	m.Cgroups = cg
+	return m.setResources(cg.Resources)
+}
+
+// Freeze toggles the unified cgroup.freeze knob and blocks until the kernel
+// reports the transition as complete via the "frozen" field of
+// cgroup.events. Unlike v1's freezer.state, cgroup.freeze takes "1"/"0" and
+// has no synchronous readback, so Freeze polls cgroup.events itself rather
+// than returning as soon as the write succeeds.
+//
+// When justContainer is true, only m.Path itself is toggled; any
+// sub-cgroups nested under it (e.g. ones created by an exec'd process) are
+// left alone. Otherwise every cgroup in the subtree is toggled.
+func (m *Manager) Freeze(state configs.FreezerState, justContainer bool) error {
+	var freeze string
+	switch state {
+	case configs.Frozen:
+		freeze = "1"
+	case configs.Thawed:
+		freeze = "0"
+	default:
+		return nil
+	}
+
+	dirs := []string{m.Path}
+	if !justContainer {
+		sub, err := subtreeDirs(m.Path)
+		if err != nil {
+			return err
+		}
+		dirs = sub
+	}
+
+	prevState := m.Cgroups.Freezer
+	m.Cgroups.Freezer = state
+	for _, dir := range dirs {
+		if err := writeFile(dir, "cgroup.freeze", freeze); err != nil {
+			m.Cgroups.Freezer = prevState
+			return err
+		}
+	}
+	for _, dir := range dirs {
+		if err := waitFrozen(dir, state == configs.Frozen); err != nil {
+			m.Cgroups.Freezer = prevState
+			return err
+		}
+	}
+	return nil
+}
+
+// freezeTimeout and freezePollInterval bound how long Freeze waits for
+// cgroup.events to report the transition it just requested as complete.
+const (
+	freezeTimeout      = 10 * time.Second
+	freezePollInterval = 10 * time.Millisecond
+)
+
+// waitFrozen polls dir's cgroup.events until its "frozen" field matches
+// wantFrozen, since writing cgroup.freeze only requests the transition --
+// the kernel reports completion asynchronously, and callers like Signal
+// depend on the freeze having actually taken effect before they enumerate
+// cgroup.procs.
+func waitFrozen(dir string, wantFrozen bool) error {
+	deadline := time.Now().Add(freezeTimeout)
+	for {
+		raw, err := readFile(dir, "cgroup.events")
+		if err != nil {
+			return err
+		}
+		if frozen, ok := parseFrozenField(raw); ok && frozen == wantFrozen {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("fs2: timed out waiting for %s to report frozen=%v", dir, wantFrozen)
+		}
+		time.Sleep(freezePollInterval)
+	}
+}
+
+// parseFrozenField extracts the "frozen" field of a cgroup.events file's
+// "key value\n"-per-line contents.
+func parseFrozenField(raw string) (frozen, ok bool) {
+	for _, line := range strings.Split(strings.TrimSpace(raw), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == "frozen" {
+			return fields[1] == "1", true
+		}
+	}
+	return false, false
+}
+
+// Signal freezes the subtree rooted at m.Path, sends sig to every pid found
+// in cgroup.procs across that subtree, then thaws it again. Freezing first
+// means an exec'd child can't reparent or fork its way out of the signal,
+// which is the failure mode a plain GetPids-then-kill loop is prone to.
+func (m *Manager) Signal(sig syscall.Signal, includeSubcgroups bool) error {
+	if err := m.Freeze(configs.Frozen, !includeSubcgroups); err != nil {
+		return err
+	}
+	defer m.Freeze(configs.Thawed, !includeSubcgroups)
+
+	dirs := []string{m.Path}
+	if includeSubcgroups {
+		sub, err := subtreeDirs(m.Path)
+		if err != nil {
+			return err
+		}
+		dirs = sub
+	}
+
+	for _, dir := range dirs {
+		pids, err := cgroups.ReadProcsFile(dir)
+		if err != nil {
+			return err
+		}
+		for _, pid := range pids {
+			if err := syscall.Kill(pid, sig); err != nil && err != syscall.ESRCH {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// subtreeDirs returns path and every directory nested under it.
+func subtreeDirs(path string) ([]string, error) {
+	var dirs []string
+	err := filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			dirs = append(dirs, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dirs, nil
+}
+
+func writeFile(dir, file, data string) error {
+	return os.WriteFile(filepath.Join(dir, file), []byte(data), 0700)
+}
+
+func readFile(dir, file string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, file))
+	return string(data), err
+}
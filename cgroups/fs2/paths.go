@@ -0,0 +1,77 @@
+package fs2
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/libcontainer/cgroups"
+)
+
+// wantControllers is the set of v2 controllers this package knows how to
+// translate configs.Resources into. hugetlb and net controllers are not
+// yet wired up on the unified hierarchy.
+var wantControllers = []string{"memory", "cpu", "cpuset", "io", "pids"}
+
+// enableControllers walks from the unified root down to dir, writing each
+// entry of wantControllers (intersected with what's actually available) to
+// the ancestor's cgroup.subtree_control so the controller becomes visible in
+// dir. v2 requires this top-down enablement; simply creating dir is not
+// enough to see e.g. memory.max appear in it.
+//
+// When rootless is true, a permission error enabling a controller on an
+// ancestor the caller doesn't own (e.g. the delegation boundary itself,
+// which a systemd Delegate=yes unit already enables controllers on) is
+// swallowed rather than failing the whole walk.
+func enableControllers(dir string, rootless bool) error {
+	root, err := cgroups.FindCgroupMountpoint("")
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(dir, root) {
+		return fmt.Errorf("fs2: %s is not under unified root %s", dir, root)
+	}
+
+	rel, err := filepath.Rel(root, dir)
+	if err != nil {
+		return err
+	}
+
+	cur := root
+	for _, part := range strings.Split(rel, string(filepath.Separator)) {
+		if err := enableControllersAt(cur); err != nil && !(rootless && isIgnorableError(err)) {
+			return err
+		}
+		cur = filepath.Join(cur, part)
+		if err := os.MkdirAll(cur, 0755); err != nil && !os.IsExist(err) {
+			if rootless && isIgnorableError(err) {
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func enableControllersAt(dir string) error {
+	available, err := readFile(dir, "cgroup.controllers")
+	if err != nil {
+		return err
+	}
+
+	var toEnable []string
+	for _, want := range wantControllers {
+		for _, have := range strings.Fields(available) {
+			if want == have {
+				toEnable = append(toEnable, "+"+want)
+				break
+			}
+		}
+	}
+	if len(toEnable) == 0 {
+		return nil
+	}
+
+	return writeFile(dir, "cgroup.subtree_control", strings.Join(toEnable, " "))
+}
@@ -0,0 +1,47 @@
+package fs2
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEnableControllersAt(t *testing.T) {
+	cases := []struct {
+		name       string
+		available  string
+		wantWrite  bool
+		wantToggle string
+	}{
+		{"all wanted controllers available", "cpuset cpu io memory pids misc", true, "+memory +cpu +cpuset +io +pids"},
+		{"only some available", "cpu memory", true, "+memory +cpu"},
+		{"none available", "misc", false, ""},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "cgroup.controllers"), []byte(c.available), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := enableControllersAt(dir); err != nil {
+				t.Fatalf("enableControllersAt: %v", err)
+			}
+
+			got, err := os.ReadFile(filepath.Join(dir, "cgroup.subtree_control"))
+			if c.wantWrite && err != nil {
+				t.Fatalf("expected cgroup.subtree_control to be written, got error: %v", err)
+			}
+			if !c.wantWrite {
+				if err == nil {
+					t.Fatalf("expected no write, got cgroup.subtree_control = %q", got)
+				}
+				return
+			}
+			if string(got) != c.wantToggle {
+				t.Errorf("cgroup.subtree_control = %q, want %q", got, c.wantToggle)
+			}
+		})
+	}
+}
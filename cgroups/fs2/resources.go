@@ -0,0 +1,203 @@
+package fs2
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/docker/libcontainer/cgroups"
+	"github.com/docker/libcontainer/configs"
+)
+
+// setResources translates the v1-shaped configs.Resources into the v2 file
+// names and writes them into m.Path. Unlike v1, where each controller lives
+// in its own directory and is set independently, all of these live side by
+// side in the same unified cgroup directory.
+func (m *Manager) setResources(r *configs.Resources) error {
+	if r == nil {
+		return nil
+	}
+	if err := m.setMemory(r); err != nil {
+		return err
+	}
+	if err := m.setCPU(r); err != nil {
+		return err
+	}
+	if err := m.setCpuset(r); err != nil {
+		return err
+	}
+	if err := m.setIO(r); err != nil {
+		return err
+	}
+	if err := m.setPids(r); err != nil {
+		return err
+	}
+	return m.setDevices(r)
+}
+
+func (m *Manager) setMemory(r *configs.Resources) error {
+	if r.Memory != 0 {
+		if err := writeFile(m.Path, "memory.max", strconv.FormatInt(r.Memory, 10)); err != nil {
+			return err
+		}
+	}
+	if r.MemorySwap != 0 {
+		if err := writeFile(m.Path, "memory.swap.max", strconv.FormatInt(r.MemorySwap, 10)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Manager) setCPU(r *configs.Resources) error {
+	if r.CpuQuota != 0 {
+		if err := writeFile(m.Path, "cpu.max", cpuMaxFile(r.CpuQuota, r.CpuPeriod)); err != nil {
+			return err
+		}
+	}
+	if r.CpuShares != 0 {
+		if err := writeFile(m.Path, "cpu.weight", strconv.FormatUint(cpuSharesToWeight(r.CpuShares), 10)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cpuSharesToWeight converts a v1-style cpu.shares value (2-262144) onto
+// the v2 cpu.weight scale (1-10000), per the kernel's documented mapping.
+func cpuSharesToWeight(shares uint64) uint64 {
+	return 1 + ((shares-2)*9999)/262142
+}
+
+// cpuMaxFile formats quota/period into the contents of the cpu.max file:
+// "max <period>" when quota is unlimited (<=0), otherwise "<quota> <period>".
+func cpuMaxFile(quota int64, period uint64) string {
+	if period == 0 {
+		period = 100000
+	}
+	q := "max"
+	if quota > 0 {
+		q = strconv.FormatInt(quota, 10)
+	}
+	return fmt.Sprintf("%s %d", q, period)
+}
+
+func (m *Manager) setCpuset(r *configs.Resources) error {
+	if r.CpusetCpus != "" {
+		if err := writeFile(m.Path, "cpuset.cpus", r.CpusetCpus); err != nil {
+			return err
+		}
+	}
+	if r.CpusetMems != "" {
+		if err := writeFile(m.Path, "cpuset.mems", r.CpusetMems); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Manager) setIO(r *configs.Resources) error {
+	if r.BlkioWeight != 0 {
+		return writeFile(m.Path, "io.bfq.weight", strconv.FormatUint(uint64(r.BlkioWeight), 10))
+	}
+	return nil
+}
+
+func (m *Manager) setPids(r *configs.Resources) error {
+	if r.PidsLimit == 0 {
+		return nil
+	}
+	limit := "max"
+	if r.PidsLimit > 0 {
+		limit = strconv.FormatInt(r.PidsLimit, 10)
+	}
+	return writeFile(m.Path, "pids.max", limit)
+}
+
+func statMemory(path string, stats *cgroups.Stats) error {
+	raw, err := readFile(path, "memory.stat")
+	if err != nil {
+		return err
+	}
+	stats.MemoryStats.Stats = parseFlatKeyed(raw)
+	return nil
+}
+
+func statIO(path string, stats *cgroups.Stats) error {
+	raw, err := readFile(path, "io.stat")
+	if err != nil {
+		return err
+	}
+	stats.BlkioStats.IoServiceBytesRecursive = nil
+	for _, line := range strings.Split(strings.TrimSpace(raw), "\n") {
+		if line == "" {
+			continue
+		}
+		fields := strings.Fields(line)
+		entry := cgroups.BlkioStatEntry{Device: fields[0]}
+		for _, kv := range fields[1:] {
+			parts := strings.SplitN(kv, "=", 2)
+			if len(parts) != 2 {
+				continue
+			}
+			v, err := strconv.ParseUint(parts[1], 10, 64)
+			if err != nil {
+				continue
+			}
+			if parts[0] == "rbytes" || parts[0] == "wbytes" {
+				entry.Value += v
+			}
+		}
+		stats.BlkioStats.IoServiceBytesRecursive = append(stats.BlkioStats.IoServiceBytesRecursive, entry)
+	}
+	return nil
+}
+
+func statCPU(path string, stats *cgroups.Stats) error {
+	raw, err := readFile(path, "cpu.stat")
+	if err != nil {
+		return err
+	}
+	for k, v := range parseFlatKeyed(raw) {
+		switch k {
+		case "usage_usec":
+			stats.CpuStats.CpuUsage.TotalUsage = v * 1000
+		case "user_usec":
+			stats.CpuStats.CpuUsage.UsageInUsermode = v * 1000
+		case "system_usec":
+			stats.CpuStats.CpuUsage.UsageInKernelmode = v * 1000
+		}
+	}
+	return nil
+}
+
+func statPids(path string, stats *cgroups.Stats) error {
+	raw, err := readFile(path, "pids.current")
+	if err != nil {
+		return err
+	}
+	v, err := strconv.ParseUint(strings.TrimSpace(raw), 10, 64)
+	if err != nil {
+		return err
+	}
+	stats.PidsStats.Current = v
+	return nil
+}
+
+// parseFlatKeyed parses the "key value\n" per-line format shared by
+// memory.stat, cpu.stat, etc.
+func parseFlatKeyed(raw string) map[string]uint64 {
+	out := make(map[string]uint64)
+	for _, line := range strings.Split(strings.TrimSpace(raw), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		v, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		out[fields[0]] = v
+	}
+	return out
+}
@@ -0,0 +1,42 @@
+package fs2
+
+import "testing"
+
+func TestCpuSharesToWeight(t *testing.T) {
+	cases := []struct {
+		shares uint64
+		want   uint64
+	}{
+		{2, 1},
+		{1024, 39},
+		{262144, 10000},
+	}
+
+	for _, c := range cases {
+		if got := cpuSharesToWeight(c.shares); got != c.want {
+			t.Errorf("cpuSharesToWeight(%d) = %d, want %d", c.shares, got, c.want)
+		}
+	}
+}
+
+func TestCpuMaxFile(t *testing.T) {
+	cases := []struct {
+		name   string
+		quota  int64
+		period uint64
+		want   string
+	}{
+		{"unlimited quota defaults period", -1, 0, "max 100000"},
+		{"unlimited quota with explicit period", -1, 50000, "max 50000"},
+		{"positive quota defaults period", 20000, 0, "20000 100000"},
+		{"positive quota with explicit period", 20000, 50000, "20000 50000"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := cpuMaxFile(c.quota, c.period); got != c.want {
+				t.Errorf("cpuMaxFile(%d, %d) = %q, want %q", c.quota, c.period, got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package fs2
+
+import (
+	"os"
+	"syscall"
+)
+
+// isIgnorableError reports whether err is a permission-denied error
+// (EACCES, EPERM, or EROFS) of the kind a rootless Manager should swallow
+// rather than fail on, since a non-root user can only ever expect to
+// configure the parts of a delegated cgroup tree it actually has rights to.
+func isIgnorableError(err error) bool {
+	switch e := err.(type) {
+	case *os.PathError:
+		err = e.Err
+	case *os.LinkError:
+		err = e.Err
+	case *os.SyscallError:
+		err = e.Err
+	}
+	errno, ok := err.(syscall.Errno)
+	if !ok {
+		return false
+	}
+	return errno == syscall.EACCES || errno == syscall.EPERM || errno == syscall.EROFS
+}
@@ -0,0 +1,259 @@
+// Package systemd implements a cgroups.Manager that drives a container's
+// cgroup through a systemd transient scope rather than writing cgroupfs
+// directly. systemd only exposes a handful of resource knobs as unit
+// properties (memory, cpu shares, block IO weight, task count); everything
+// else is configured by falling back to cgroups/fs (v1 hosts) or
+// cgroups/fs2 (cgroup-v2 hosts) against the same Delegate=true cgroup
+// directory (or directories) systemd created for the scope.
+package systemd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	systemdDbus "github.com/coreos/go-systemd/dbus"
+	"github.com/godbus/dbus"
+
+	"github.com/docker/libcontainer/cgroups"
+	"github.com/docker/libcontainer/cgroups/fs"
+	"github.com/docker/libcontainer/cgroups/fs2"
+	"github.com/docker/libcontainer/configs"
+)
+
+var _ cgroups.Manager = (*Manager)(nil)
+
+type Manager struct {
+	Cgroups *configs.Cgroup
+	Paths   map[string]string
+
+	// v2, when non-nil, means the host's only hierarchy is the unified
+	// (cgroup2) one, and every method below delegates to it instead of
+	// falling back to cgroups/fs's per-controller v1 codepath.
+	v2 *fs2.Manager
+}
+
+// managedSubsystems is every v1 controller a Delegate=true transient scope
+// gets a matching cgroup directory in, whether or not systemd exposes a
+// unit property for it.
+var managedSubsystems = []string{
+	"memory", "cpu", "cpuacct", "blkio", "pids",
+	"devices", "cpuset", "freezer", "perf_event",
+}
+
+var (
+	connLock sync.Mutex
+	theConn  *systemdDbus.Conn
+)
+
+func systemdConn() (*systemdDbus.Conn, error) {
+	connLock.Lock()
+	defer connLock.Unlock()
+
+	if theConn != nil {
+		return theConn, nil
+	}
+	conn, err := systemdDbus.New()
+	if err != nil {
+		return nil, err
+	}
+	theConn = conn
+	return conn, nil
+}
+
+// UseSystemd reports whether the systemd private D-Bus socket is reachable,
+// i.e. whether a systemd Manager can be used at all on this host.
+func UseSystemd() bool {
+	_, err := systemdConn()
+	return err == nil
+}
+
+// unitName splits configs.Cgroup.Parent into the slice the transient scope
+// should live under and the scope's own unit name, so callers can pass
+// either "machine.slice/foo.scope" or a plain path and have this package
+// pick the right one apart. A parent with no slash is treated as a bare
+// slice name, and the container's own name becomes the scope.
+func unitName(c *configs.Cgroup) (slice, unit string) {
+	parent := c.Parent
+	if parent == "" {
+		parent = "system.slice"
+	}
+
+	if strings.Contains(parent, "/") {
+		slice, unit = filepath.Dir(parent), filepath.Base(parent)
+	} else {
+		slice = parent
+	}
+	if unit == "" || unit == "." {
+		unit = c.Name + ".scope"
+	}
+	return slice, unit
+}
+
+func newProp(name string, value interface{}) systemdDbus.Property {
+	return systemdDbus.Property{
+		Name:  name,
+		Value: dbus.MakeVariant(value),
+	}
+}
+
+func (m *Manager) Apply(pid int) error {
+	conn, err := systemdConn()
+	if err != nil {
+		return err
+	}
+
+	slice, unit := unitName(m.Cgroups)
+	res := m.Cgroups.Resources
+
+	properties := []systemdDbus.Property{
+		systemdDbus.PropDescription(fmt.Sprintf("libcontainer container %s", m.Cgroups.Name)),
+		systemdDbus.PropSlice(slice),
+		systemdDbus.PropPids(uint32(pid)),
+		newProp("Delegate", true),
+	}
+
+	if res != nil {
+		if res.Memory != 0 {
+			properties = append(properties, newProp("MemoryLimit", uint64(res.Memory)))
+		}
+		if res.CpuShares != 0 {
+			properties = append(properties, newProp("CPUShares", uint64(res.CpuShares)))
+		}
+		if res.BlkioWeight != 0 {
+			properties = append(properties, newProp("BlockIOWeight", uint64(res.BlkioWeight)))
+		}
+		if res.PidsLimit > 0 {
+			properties = append(properties, newProp("TasksMax", uint64(res.PidsLimit)))
+		}
+	}
+
+	done := make(chan string, 1)
+	if _, err := conn.StartTransientUnit(unit, "replace", properties, done); err != nil {
+		return err
+	}
+	<-done
+
+	if cgroups.IsCgroup2UnifiedMode() {
+		return m.applyUnified(slice, unit)
+	}
+
+	paths, err := unitPaths(slice, unit)
+	if err != nil {
+		return err
+	}
+	m.Paths = paths
+
+	// Everything systemd doesn't expose as a unit property still needs the
+	// plain cgroupfs codepath. Handing fs.Manager our already-resolved
+	// Paths makes Apply join-only (see cgroups/fs's rootless/Paths-override
+	// support) instead of trying to create a second, unmanaged cgroup tree.
+	if err := m.fsManager().Apply(pid); err != nil {
+		return err
+	}
+	if err := fs.ApplyDevices(m.Cgroups, pid); err != nil {
+		return err
+	}
+	return fs.ApplyCpuset(m.Cgroups, pid)
+}
+
+// applyUnified is Apply's cgroup-v2 counterpart. On a unified host systemd
+// creates a single delegated directory for the scope instead of one per
+// controller, so the v1 fallback (fsManager/ApplyDevices/ApplyCpuset, all
+// of which resolve per-controller mountpoints that don't exist under v2)
+// doesn't apply; an fs2.Manager rooted at that single directory covers
+// everything systemd itself doesn't expose as a unit property. The pid is
+// already a member of it by virtue of StartTransientUnit's PropPids, so
+// only the resource knobs still need to be pushed.
+func (m *Manager) applyUnified(slice, unit string) error {
+	root, err := cgroups.FindCgroupMountpoint("")
+	if err != nil {
+		return err
+	}
+
+	m.v2 = fs2.NewManager(m.Cgroups, root, filepath.Join(slice, unit), false)
+	m.Paths = m.v2.GetPaths()
+
+	return m.v2.Set(m.Cgroups)
+}
+
+func unitPaths(slice, unit string) (map[string]string, error) {
+	paths := make(map[string]string)
+	for _, name := range managedSubsystems {
+		mountpoint, err := cgroups.FindCgroupMountpoint(name)
+		if err != nil {
+			// Not every host mounts every controller; skip what's missing
+			// rather than fail the whole scope.
+			continue
+		}
+		paths[name] = filepath.Join(mountpoint, slice, unit)
+	}
+	return paths, nil
+}
+
+// fsManager returns an *fs.Manager scoped to the cgroup directories systemd
+// created for our unit, used to cover everything systemd itself doesn't
+// expose as a unit property.
+func (m *Manager) fsManager() *fs.Manager {
+	return &fs.Manager{Cgroups: m.Cgroups, Paths: m.Paths}
+}
+
+func (m *Manager) Destroy() error {
+	// On v2 systemd owns the single delegated directory and removes it
+	// itself on StopUnit; on v1 we own the per-controller directories
+	// fsManager() resolved and must clean those up ourselves first.
+	if m.v2 == nil {
+		if err := m.fsManager().Destroy(); err != nil {
+			return err
+		}
+	}
+
+	conn, err := systemdConn()
+	if err != nil {
+		return err
+	}
+	_, unit := unitName(m.Cgroups)
+	done := make(chan string, 1)
+	if _, err := conn.StopUnit(unit, "replace", done); err != nil {
+		return err
+	}
+	<-done
+	return nil
+}
+
+func (m *Manager) GetPaths() map[string]string {
+	return m.Paths
+}
+
+func (m *Manager) GetStats() (*cgroups.Stats, error) {
+	if m.v2 != nil {
+		return m.v2.GetStats()
+	}
+	return m.fsManager().GetStats()
+}
+
+func (m *Manager) Freeze(state configs.FreezerState, justContainer bool) error {
+	if m.v2 != nil {
+		return m.v2.Freeze(state, justContainer)
+	}
+	return m.fsManager().Freeze(state, justContainer)
+}
+
+func (m *Manager) GetPids() ([]int, error) {
+	if m.v2 != nil {
+		return m.v2.GetPids()
+	}
+	return m.fsManager().GetPids()
+}
+
+// Set updates the unit's resource limits by writing every knob straight
+// into the scope's cgroup directories through cgroups/fs (or cgroups/fs2 on
+// a unified host). The unit's systemd properties are not updated over D-Bus.
+func (m *Manager) Set(container *configs.Config) error {
+	m.Cgroups = container.Cgroups
+	if m.v2 != nil {
+		return m.v2.Set(container.Cgroups)
+	}
+	return m.fsManager().Set(container)
+}
@@ -0,0 +1,32 @@
+package systemd
+
+import (
+	"testing"
+
+	"github.com/docker/libcontainer/configs"
+)
+
+func TestUnitName(t *testing.T) {
+	cases := []struct {
+		name       string
+		parent     string
+		cgroupName string
+		wantSlice  string
+		wantUnit   string
+	}{
+		{"empty parent defaults to system.slice", "", "foo", "system.slice", "foo.scope"},
+		{"bare slice name", "machine.slice", "foo", "machine.slice", "foo.scope"},
+		{"slice plus explicit unit", "machine.slice/foo.scope", "foo", "machine.slice", "foo.scope"},
+		{"nested slice plus explicit unit", "a.slice/b.slice/foo.scope", "foo", "a.slice/b.slice", "foo.scope"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cg := &configs.Cgroup{Name: c.cgroupName, Parent: c.parent}
+			slice, unit := unitName(cg)
+			if slice != c.wantSlice || unit != c.wantUnit {
+				t.Errorf("unitName(%+v) = (%q, %q), want (%q, %q)", cg, slice, unit, c.wantSlice, c.wantUnit)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+// Package configs defines the data libcontainer's cgroup backends
+// (cgroups/fs, cgroups/fs2, cgroups/systemd) are driven by: a container's
+// cgroup placement and freezer state (Cgroup), its resource limits
+// (Resources), and the subset of the container config those backends need
+// (Config).
+package configs
+
+// Config holds a container's configuration. Only the fields the cgroup
+// backends need are defined here.
+type Config struct {
+	// Cgroups specifies the container's cgroup placement and limits.
+	Cgroups *Cgroup
+}
+
+// Cgroup specifies the cgroup a container's processes are placed into and
+// the limits applied to it.
+type Cgroup struct {
+	// Name is the final path component of the container's own cgroup
+	// directory, joined onto Parent.
+	Name string
+
+	// Parent is the cgroup the container's own cgroup is created under.
+	// cgroups/fs treats it as a plain path: an absolute Parent is taken
+	// relative to each controller's mountpoint, a relative one relative to
+	// the init process's cgroup in that controller. cgroups/fs2 joins it
+	// onto the unified mountpoint. cgroups/systemd's unitName additionally
+	// accepts "machine.slice/foo.scope"-shaped values and splits them into
+	// the slice the transient scope lives under and the scope's own unit
+	// name.
+	Parent string
+
+	// Resources are the limits applied to the cgroup.
+	Resources *Resources
+
+	// Freezer records the last freezer state Manager.Freeze requested, so
+	// a caller that reloads a Cgroup from a state file can tell whether
+	// the container was frozen.
+	Freezer FreezerState
+}
+
+// FreezerState is the state of a container's freezer cgroup.
+type FreezerState string
+
+const (
+	// Undefined means Manager.Freeze has never been called for this
+	// Cgroup.
+	Undefined FreezerState = ""
+	// Frozen means the container's processes are stopped.
+	Frozen FreezerState = "FROZEN"
+	// Thawed means the container's processes are running normally.
+	Thawed FreezerState = "THAWED"
+)
@@ -0,0 +1,100 @@
+package configs
+
+import "strconv"
+
+// Resources specifies the resource limits a cgroup Manager applies. A zero
+// value for any numeric field means "leave the controller's default
+// alone" rather than "set a limit of zero" -- callers that want an actual
+// zero limit need a controller-specific knob this struct doesn't expose
+// yet.
+type Resources struct {
+	// Memory is the memory limit in bytes (memory.limit_in_bytes /
+	// memory.max).
+	Memory int64
+	// MemorySwap is the memory+swap limit in bytes
+	// (memory.memsw.limit_in_bytes / memory.swap.max).
+	MemorySwap int64
+
+	// CpuShares is the relative CPU shares, 2-262144 (cpu.shares).
+	CpuShares uint64
+	// CpuPeriod is the CFS scheduling period in microseconds
+	// (cpu.cfs_period_us).
+	CpuPeriod uint64
+	// CpuQuota is the CFS quota in microseconds per CpuPeriod
+	// (cpu.cfs_quota_us). A negative value means unlimited.
+	CpuQuota int64
+
+	// CpusetCpus is the cpuset.cpus value, e.g. "0-3,7".
+	CpusetCpus string
+	// CpusetMems is the cpuset.mems value, e.g. "0-1".
+	CpusetMems string
+
+	// BlkioWeight is the relative block IO weight, 10-1000
+	// (blkio.weight / io.bfq.weight).
+	BlkioWeight uint16
+
+	// PidsLimit is the maximum number of tasks the cgroup may contain
+	// (pids.max). A negative value means unlimited.
+	PidsLimit int64
+
+	// Devices is the device whitelist (devices.allow/devices.deny).
+	Devices []*Device
+
+	// HugetlbLimit is the set of per-page-size hugetlb limits
+	// (hugetlb.<pagesize>.limit_in_bytes).
+	HugetlbLimit []*HugepageLimit
+
+	// NetClsClassid is the net_cls.classid tag applied to the container's
+	// network traffic.
+	NetClsClassid uint32
+
+	// NetPrioIfpriomap is the set of per-interface priorities
+	// (net_prio.ifpriomap).
+	NetPrioIfpriomap []*IfPrioMap
+}
+
+// HugepageLimit is a single hugetlb.<Pagesize>.limit_in_bytes entry.
+type HugepageLimit struct {
+	// Pagesize is the hugetlb page size this limit applies to, e.g. "2MB".
+	Pagesize string
+	// Limit is the limit in bytes.
+	Limit uint64
+}
+
+// IfPrioMap is a single net_prio.ifpriomap entry.
+type IfPrioMap struct {
+	// Interface is the network interface name.
+	Interface string
+	// Priority is the priority assigned to traffic on Interface.
+	Priority int64
+}
+
+// Device describes a single device.allow/device.deny entry.
+type Device struct {
+	// Type is the device type: 'c' for character, 'b' for block, or 'a'
+	// for "all devices".
+	Type rune
+	// Major is the device's major number, or -1 to mean "any".
+	Major int64
+	// Minor is the device's minor number, or -1 to mean "any".
+	Minor int64
+	// Permissions is the access permitted: any combination of 'r' (read),
+	// 'w' (write) and 'm' (mknod).
+	Permissions string
+	// Allow is true for a devices.allow entry, false for devices.deny.
+	Allow bool
+}
+
+// CgroupString formats the device the way devices.allow/devices.deny
+// expect: "<type> <major>:<minor> <permissions>", with "*" standing in for
+// a major/minor of -1.
+func (d *Device) CgroupString() string {
+	major, minor := "*", "*"
+	if d.Major >= 0 {
+		major = strconv.FormatInt(d.Major, 10)
+	}
+	if d.Minor >= 0 {
+		minor = strconv.FormatInt(d.Minor, 10)
+	}
+	return string(d.Type) + " " + major + ":" + minor + " " + d.Permissions
+}
@@ -0,0 +1,14 @@
+package libcontainer
+
+import (
+	"github.com/docker/libcontainer/configs"
+)
+
+// UpdateResources retunes a running container's resource limits in place,
+// without a restart. It's the entry point orchestrators (Docker, Nomad,
+// kubelet) use to apply a live memory/cpu/etc. update, backed by the
+// cgroup Manager's own Set.
+func (c *linuxContainer) UpdateResources(r *configs.Resources) error {
+	c.config.Cgroups.Resources = r
+	return c.cgroupManager.Set(c.config)
+}